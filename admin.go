@@ -0,0 +1,41 @@
+package main
+
+// Admin endpoints for operating the dataset: triggering a reload on
+// demand and checking what's currently loaded.
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// requireAdminToken returns Gin middleware enforcing a shared-secret
+// bearer token on admin routes. If cfg.AdminToken is unset, admin routes
+// refuse every request rather than running wide open by default.
+func requireAdminToken(cfg *Config) gin.HandlerFunc {
+	return wrap(func(ctx *Context) {
+		if cfg.AdminToken == "" || ctx.GetHeader("Authorization") != "Bearer "+cfg.AdminToken {
+			ctx.Unauthorized("missing or invalid admin token")
+			return
+		}
+		ctx.Next()
+	})
+}
+
+// adminReloadHandler handles POST /admin/reload: re-parse the CSV file
+// and atomically swap it into place.
+func adminReloadHandler(store *ItemStore) handlerFunc {
+	return func(ctx *Context) {
+		if err := store.Reload(); err != nil {
+			ctx.ServerError(err)
+			return
+		}
+		ctx.Success(gin.H{"dataset": store.Status()})
+	}
+}
+
+// adminStatusHandler handles GET /admin/status: report the current
+// dataset version, row count, load duration, and last-reload timestamp.
+func adminStatusHandler(store *ItemStore) handlerFunc {
+	return func(ctx *Context) {
+		ctx.Success(gin.H{"dataset": store.Status()})
+	}
+}
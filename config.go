@@ -0,0 +1,67 @@
+package main
+
+// This file introduces a Config struct so the service's runtime behavior
+// (listen address, pagination limits, which search backend to use, ...)
+// can be set from a config file or environment variables instead of
+// being hard-coded, mirroring how other services in this family pass an
+// explicit cfg into route registration rather than reading globals ad
+// hoc from inside handlers.
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Config holds every tunable the service reads at startup.
+type Config struct {
+	CSVPath         string `mapstructure:"csv_path"`
+	ListenAddr      string `mapstructure:"listen_addr"`
+	MaxItemsPerPage int    `mapstructure:"max_items_per_page"`
+	DefaultLimit    int    `mapstructure:"default_limit"`
+	EnableMetrics   bool   `mapstructure:"enable_metrics"`
+	AdminToken      string `mapstructure:"admin_token"`
+
+	// Search backend selection. SearchBackend is "memory" (the default)
+	// or "meilisearch"; the Meilisearch* fields are only read when it's
+	// "meilisearch".
+	SearchBackend        string `mapstructure:"search_backend"`
+	MeilisearchHost      string `mapstructure:"meilisearch_host"`
+	MeilisearchAPIKey    string `mapstructure:"meilisearch_api_key"`
+	MeilisearchIndexName string `mapstructure:"meilisearch_index_name"`
+}
+
+// LoadConfig reads ./config.yaml if present, then layers GCA_-prefixed
+// environment variables on top (e.g. GCA_LISTEN_ADDR overrides
+// listen_addr), and returns the resulting Config. A missing config file
+// is not an error - the service should start with sane defaults even
+// with zero configuration.
+func LoadConfig() (*Config, error) {
+	v := viper.New()
+	v.SetDefault("csv_path", "data/Warehouse_and_Retail_Sales.csv")
+	v.SetDefault("listen_addr", ":8080")
+	v.SetDefault("max_items_per_page", 50)
+	v.SetDefault("default_limit", 10)
+	v.SetDefault("enable_metrics", false)
+	v.SetDefault("search_backend", "memory")
+	v.SetDefault("meilisearch_index_name", "items")
+
+	v.SetConfigName("config")
+	v.SetConfigType("yaml")
+	v.AddConfigPath(".")
+	if err := v.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return nil, err
+		}
+	}
+
+	v.SetEnvPrefix("GCA")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+// This file adds a thin wrapper around *gin.Context so handlers stop
+// hand-rolling the same gin.H{"status": ..., "timestamp": ...} envelope
+// in every function. It's the foundation for keeping response shapes
+// consistent as the API surface keeps growing.
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context embeds *gin.Context and adds a handful of response helpers
+// that always stamp the shared envelope fields (status, timestamp).
+type Context struct {
+	*gin.Context
+}
+
+// handlerFunc is the signature used by handlers registered through
+// addRoutes. wrap adapts one into a gin.HandlerFunc.
+type handlerFunc func(*Context)
+
+func wrap(fn handlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fn(&Context{Context: c})
+	}
+}
+
+// envelope builds the shared response body, merging extra on top of the
+// status/timestamp fields every response carries.
+func envelope(status string, extra gin.H) gin.H {
+	body := gin.H{
+		"status":    status,
+		"timestamp": time.Now().Format(time.RFC3339),
+	}
+	for k, v := range extra {
+		body[k] = v
+	}
+	return body
+}
+
+// Success responds 200 OK, merging extra (e.g. {"data": ..., "count": ...})
+// on top of the shared status/timestamp envelope.
+func (ctx *Context) Success(extra gin.H) {
+	ctx.JSON(http.StatusOK, envelope("success", extra))
+}
+
+// NotFound responds 404 with msg as the error message.
+func (ctx *Context) NotFound(msg string) {
+	ctx.JSON(http.StatusNotFound, envelope("error", gin.H{"error": msg}))
+}
+
+// DataFormat responds 400 Bad Request for input that failed to parse or
+// validate - a malformed filter expression, an unreadable CSV upload, and
+// the like.
+func (ctx *Context) DataFormat(err error) {
+	ctx.JSON(http.StatusBadRequest, envelope("error", gin.H{"error": err.Error()}))
+}
+
+// ServerError responds 500 for failures that aren't the caller's fault
+// (a file I/O error, a search backend outage, ...).
+func (ctx *Context) ServerError(err error) {
+	ctx.JSON(http.StatusInternalServerError, envelope("error", gin.H{"error": err.Error()}))
+}
+
+// Unauthorized responds 401 for requests that failed an auth check.
+func (ctx *Context) Unauthorized(msg string) {
+	ctx.AbortWithStatusJSON(http.StatusUnauthorized, envelope("error", gin.H{"error": msg}))
+}
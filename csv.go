@@ -0,0 +1,174 @@
+package main
+
+// This file implements a streaming CSV reader. The original loadCSV
+// called reader.ReadAll() and held every row in memory at once, which
+// works fine for small files but means startup memory scales with the
+// size of the CSV. ReadCSV instead reads a bounded window of records at a
+// time, so a multi-GB CSV can be loaded (or browsed) in fixed-size
+// chunks.
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+)
+
+// chunkSize is how many records readAllItems reads per call to ReadCSV
+// while populating the in-memory dataset.
+const chunkSize = 5000
+
+// ParseError records a single row that failed to parse, so callers can
+// report exactly what went wrong instead of silently dropping the row or
+// panicking.
+type ParseError struct {
+	Line    int64  `json:"line"`    // 1-based line number within the CSV, header excluded
+	Field   string `json:"field"`   // which column failed to parse
+	Value   string `json:"value"`   // the raw value that failed
+	Message string `json:"message"` // human-readable explanation
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("line %d: field %q: %s (value %q)", e.Line, e.Field, e.Message, e.Value)
+}
+
+// ReadCSV reads up to `lines` data records from o, starting at `startLine`
+// (1-based, not counting the header row), and parses each into an Item.
+// It returns the parsed items, any per-row parse errors encountered along
+// the way (parsing continues past a bad row rather than aborting), and a
+// `more` flag indicating whether additional records remain beyond this
+// window.
+//
+// Callers that want the whole file should keep calling ReadCSV with an
+// advancing startLine until more is false.
+func ReadCSV(o io.Reader, startLine, lines int64) (out []Item, more bool, errs []ParseError, err error) {
+	reader := csv.NewReader(o)
+
+	// The header row isn't part of the line numbering, so always consume
+	// it regardless of startLine.
+	if _, err = reader.Read(); err != nil {
+		if err == io.EOF {
+			return nil, false, nil, nil
+		}
+		return nil, false, nil, err
+	}
+
+	var line int64 = 1
+	for ; line < startLine; line++ {
+		if _, err = reader.Read(); err != nil {
+			if err == io.EOF {
+				return nil, false, nil, nil
+			}
+			return nil, false, nil, err
+		}
+	}
+
+	for int64(len(out)) < lines {
+		record, rerr := reader.Read()
+		if rerr == io.EOF {
+			return out, false, errs, nil
+		}
+		if rerr != nil {
+			return out, false, errs, rerr
+		}
+
+		item, rowErrs := parseRow(record, line)
+		errs = append(errs, rowErrs...)
+		out = append(out, item)
+		line++
+	}
+
+	// There's more to read if the next Read() doesn't immediately EOF.
+	if _, rerr := reader.Read(); rerr == nil {
+		more = true
+	} else if rerr != io.EOF {
+		return out, false, errs, rerr
+	}
+
+	return out, more, errs, nil
+}
+
+// readAllItems loads the entire CSV at filepath into memory, reading
+// chunkSize records at a time off of a single csv.Reader kept positioned
+// across chunks. It's used both for the initial startup load and for
+// every hot reload. Rows that fail to parse are logged (with line number
+// and field) and skipped rather than aborting the load or panicking.
+//
+// Unlike ReadCSV (which re-opens at the header on every call, since each
+// /items/raw request is an independent, randomly-positioned window), this
+// never rewinds: rewinding per chunk would re-read every prior record on
+// every chunk, making a full load quadratic in the row count.
+func readAllItems(filepath string) ([]Item, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	if _, err := reader.Read(); err != nil { // consume the header once
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var out []Item
+	var line int64
+	for {
+		n := 0
+		for n < chunkSize {
+			record, rerr := reader.Read()
+			if rerr == io.EOF {
+				return out, nil
+			}
+			if rerr != nil {
+				return nil, rerr
+			}
+			line++
+			item, rowErrs := parseRow(record, line)
+			for _, pe := range rowErrs {
+				log.Printf("readAllItems: %s", pe.Error())
+			}
+			out = append(out, item)
+			n++
+		}
+	}
+}
+
+// parseRow converts a single CSV record into an Item, collecting a
+// ParseError for each column that fails to convert instead of silently
+// ignoring the error or aborting the whole row.
+func parseRow(r []string, line int64) (Item, []ParseError) {
+	var errs []ParseError
+
+	parseInt := func(field string, idx int) int {
+		n, err := strconv.Atoi(r[idx])
+		if err != nil {
+			errs = append(errs, ParseError{Line: line, Field: field, Value: r[idx], Message: "not a valid integer"})
+		}
+		return n
+	}
+	parseFloat := func(field string, idx int) float64 {
+		n, err := strconv.ParseFloat(r[idx], 64)
+		if err != nil {
+			errs = append(errs, ParseError{Line: line, Field: field, Value: r[idx], Message: "not a valid number"})
+		}
+		return n
+	}
+
+	item := Item{
+		Year:            parseInt("year", 0),
+		Month:           parseInt("month", 1),
+		Supplier:        r[2],
+		ItemCode:        r[3],
+		ItemDescription: r[4],
+		ItemType:        r[5],
+		RetailSales:     parseFloat("retail_sales", 6),
+		RetailTransfers: parseFloat("retail_transfers", 7),
+		WarehouseSales:  parseFloat("warehouse_sales", 8),
+	}
+	return item, errs
+}
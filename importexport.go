@@ -0,0 +1,237 @@
+package main
+
+// This file adds bulk CSV import/export endpoints. Unlike the fixed
+// column-order CSV the dataset ships as, uploaded and exported files use
+// the `csv:"GCA.*"` tagged-header convention declared on Item, so columns
+// can appear in any order (or be added later) without breaking existing
+// integrations.
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// csvTagField describes one Item field's tagged CSV column.
+type csvTagField struct {
+	header string // the "GCA.xxx" header name
+	index  int    // index into reflect.Value.Field(index)
+}
+
+// csvTagFields reflects over Item's `csv` struct tags once, in field
+// declaration order, and reuses the result for both import and export so
+// the two stay in sync with the struct automatically.
+var csvTagFields = buildCSVTagFields()
+
+func buildCSVTagFields() []csvTagField {
+	t := reflect.TypeOf(Item{})
+	fields := make([]csvTagField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("csv")
+		if tag == "" {
+			continue
+		}
+		fields = append(fields, csvTagField{header: tag, index: i})
+	}
+	return fields
+}
+
+// importItems reads a tagged-header CSV from r and parses it into Items.
+// Columns may appear in any order; any of the GCA.* columns that's
+// missing from the header is reported as a single error rather than
+// partial data being imported. Rows that fail to parse are skipped, with
+// a ParseError recorded for each (including the 1-based line number), so
+// one bad row doesn't abort the whole upload.
+func importItems(r io.Reader) (parsed []Item, errs []ParseError, err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // header-driven, so don't enforce a fixed column count
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read header: %w", err)
+	}
+
+	// Map each tagged column to the index it appears at in this upload.
+	colIndex := make(map[string]int, len(header))
+	for i, name := range header {
+		colIndex[name] = i
+	}
+	for _, f := range csvTagFields {
+		if _, ok := colIndex[f.header]; !ok {
+			return nil, nil, fmt.Errorf("missing required column %q", f.header)
+		}
+	}
+
+	var line int64
+	for {
+		record, rerr := reader.Read()
+		if rerr == io.EOF {
+			break
+		}
+		line++
+		if rerr != nil {
+			errs = append(errs, ParseError{Line: line, Message: rerr.Error()})
+			continue
+		}
+
+		item, rowErrs := parseTaggedRow(record, colIndex, line)
+		if len(rowErrs) > 0 {
+			errs = append(errs, rowErrs...)
+			continue
+		}
+		parsed = append(parsed, item)
+	}
+	return parsed, errs, nil
+}
+
+// parseTaggedRow builds an Item from one CSV record using colIndex to
+// look up each GCA.* column regardless of its position in the file.
+func parseTaggedRow(record []string, colIndex map[string]int, line int64) (Item, []ParseError) {
+	var errs []ParseError
+	var item Item
+
+	get := func(header string) string {
+		idx, ok := colIndex[header]
+		if !ok || idx >= len(record) {
+			errs = append(errs, ParseError{Line: line, Field: header, Message: "column missing from this row"})
+			return ""
+		}
+		return record[idx]
+	}
+
+	item.Year = atoiOrErr(get("GCA.year"), "GCA.year", line, &errs)
+	item.Month = atoiOrErr(get("GCA.month"), "GCA.month", line, &errs)
+	item.Supplier = get("GCA.supplier")
+	item.ItemCode = get("GCA.item_code")
+	item.ItemDescription = get("GCA.item_description")
+	item.ItemType = get("GCA.item_type")
+	item.RetailSales = atofOrErr(get("GCA.retail_sales"), "GCA.retail_sales", line, &errs)
+	item.RetailTransfers = atofOrErr(get("GCA.retail_transfers"), "GCA.retail_transfers", line, &errs)
+	item.WarehouseSales = atofOrErr(get("GCA.warehouse_sales"), "GCA.warehouse_sales", line, &errs)
+
+	return item, errs
+}
+
+func atoiOrErr(value, field string, line int64, errs *[]ParseError) int {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		*errs = append(*errs, ParseError{Line: line, Field: field, Value: value, Message: "not a valid integer"})
+	}
+	return n
+}
+
+func atofOrErr(value, field string, line int64, errs *[]ParseError) float64 {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		*errs = append(*errs, ParseError{Line: line, Field: field, Value: value, Message: "not a valid number"})
+	}
+	return n
+}
+
+// exportItems writes items to w as a tagged-header CSV, in the same
+// column order as csvTagFields, so an export can always be re-imported
+// losslessly regardless of struct field order changes.
+func exportItems(w io.Writer, data []Item) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	header := make([]string, len(csvTagFields))
+	for i, f := range csvTagFields {
+		header[i] = f.header
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, item := range data {
+		v := reflect.ValueOf(item)
+		record := make([]string, len(csvTagFields))
+		for i, f := range csvTagFields {
+			record[i] = fmt.Sprintf("%v", v.Field(f.index).Interface())
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	return writer.Error()
+}
+
+// importItemsHandler handles POST /items/import. It expects a multipart
+// form upload with a "file" field containing a tagged-header CSV. Pass
+// ?dry_run=true to validate and report errors without actually adding the
+// rows to the dataset.
+func importItemsHandler(store *ItemStore) handlerFunc {
+	return func(ctx *Context) {
+		dryRun := ctx.Query("dry_run") == "true"
+
+		fileHeader, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.DataFormat(fmt.Errorf("missing \"file\" multipart field"))
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			ctx.ServerError(err)
+			return
+		}
+		defer file.Close()
+
+		parsed, parseErrs, err := importItems(file)
+		if err != nil {
+			ctx.DataFormat(err)
+			return
+		}
+
+		if !dryRun {
+			existing := store.Current()
+			merged := make([]Item, 0, len(existing)+len(parsed))
+			merged = append(merged, existing...)
+			merged = append(merged, parsed...)
+			if err := store.Replace(merged); err != nil {
+				ctx.ServerError(err)
+				return
+			}
+		}
+
+		ctx.Success(gin.H{
+			"dry_run":      dryRun,
+			"imported":     len(parsed),
+			"row_errors":   parseErrs,
+			"total_errors": len(parseErrs),
+		})
+	}
+}
+
+// exportItemsHandler handles GET /items/export?filter=... and streams the
+// current (optionally filtered) dataset back as a tagged-header CSV.
+func exportItemsHandler(store *ItemStore) handlerFunc {
+	return func(ctx *Context) {
+		filter, err := parseFilter(ctx.Query("filter"))
+		if err != nil {
+			ctx.DataFormat(err)
+			return
+		}
+
+		var data []Item
+		for _, item := range store.Current() {
+			if filter.matches(item) {
+				data = append(data, item)
+			}
+		}
+
+		ctx.Header("Content-Disposition", "attachment; filename=items_export.csv")
+		ctx.Header("Content-Type", "text/csv")
+		if err := exportItems(ctx.Writer, data); err != nil {
+			// Headers are already flushed by this point in a streaming
+			// response, so there's nothing better to do than log it.
+			ctx.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestImportItems(t *testing.T) {
+	csv := "GCA.supplier,GCA.year,GCA.month,GCA.item_code,GCA.item_description,GCA.item_type,GCA.retail_sales,GCA.retail_transfers,GCA.warehouse_sales\n" +
+		"Acme Co,2020,1,A100,Acme Red,WINE,12.5,1,0\n" +
+		"Acme Co,2020,2,A100,Acme Red,WINE,not-a-number,1,0\n"
+
+	items, errs, err := importItems(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("importItems: unexpected error: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 successfully parsed row, got %d", len(items))
+	}
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 parse error for the bad row, got %d", len(errs))
+	}
+	if items[0].Supplier != "Acme Co" || items[0].Year != 2020 || items[0].RetailSales != 12.5 {
+		t.Fatalf("unexpected parsed item: %+v", items[0])
+	}
+}
+
+func TestImportItemsMissingColumn(t *testing.T) {
+	csv := "GCA.supplier,GCA.year\nAcme Co,2020\n"
+	if _, _, err := importItems(strings.NewReader(csv)); err == nil {
+		t.Fatalf("expected an error for a header missing required GCA.* columns")
+	}
+}
+
+func TestImportItemsColumnOrderIndependent(t *testing.T) {
+	csv := "GCA.item_code,GCA.year,GCA.month,GCA.supplier,GCA.item_description,GCA.item_type,GCA.retail_sales,GCA.retail_transfers,GCA.warehouse_sales\n" +
+		"A100,2020,1,Acme Co,Acme Red,WINE,12.5,1,0\n"
+
+	items, errs, err := importItems(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("importItems: unexpected error: %v", err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("expected no parse errors, got %v", errs)
+	}
+	if items[0].ItemCode != "A100" || items[0].Supplier != "Acme Co" {
+		t.Fatalf("unexpected parsed item: %+v", items[0])
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	original := []Item{
+		{Year: 2020, Month: 1, Supplier: "Acme Co", ItemCode: "A100", ItemDescription: "Acme Red", ItemType: "WINE", RetailSales: 12.5, RetailTransfers: 1, WarehouseSales: 0},
+		{Year: 2021, Month: 6, Supplier: "Beta LLC", ItemCode: "B200", ItemDescription: "Beta Lager", ItemType: "BEER", RetailSales: 7, RetailTransfers: 0, WarehouseSales: 3},
+	}
+
+	var buf bytes.Buffer
+	if err := exportItems(&buf, original); err != nil {
+		t.Fatalf("exportItems: unexpected error: %v", err)
+	}
+
+	roundTripped, errs, err := importItems(&buf)
+	if err != nil {
+		t.Fatalf("importItems on exported CSV: unexpected error: %v", err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("expected no parse errors round-tripping, got %v", errs)
+	}
+	if len(roundTripped) != len(original) {
+		t.Fatalf("expected %d items back, got %d", len(original), len(roundTripped))
+	}
+	for i, item := range roundTripped {
+		if item != original[i] {
+			t.Errorf("item %d did not round-trip: got %+v, want %+v", i, item, original[i])
+		}
+	}
+}
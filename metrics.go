@@ -0,0 +1,53 @@
+package main
+
+// This file wires up the optional request-metrics surface gated by
+// Config.EnableMetrics. It's a small hand-rolled counter rather than
+// pulling in a Prometheus client, matching how this repo prefers a
+// simple dependency-free implementation (see the filter DSL and
+// typo-tolerant matcher in search.go) over a heavier library when a few
+// atomic counters will do.
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics holds process-lifetime request counters. All fields are safe
+// for concurrent use.
+type Metrics struct {
+	startedAt    time.Time
+	requestCount atomic.Int64
+	errorCount   atomic.Int64 // responses with status >= 500
+}
+
+// NewMetrics returns a Metrics ready to be wired into metricsMiddleware.
+func NewMetrics() *Metrics {
+	return &Metrics{startedAt: time.Now()}
+}
+
+// metricsMiddleware counts every request that passes through it, and
+// every response that came back with a 5xx status.
+func metricsMiddleware(m *Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		m.requestCount.Add(1)
+		if c.Writer.Status() >= 500 {
+			m.errorCount.Add(1)
+		}
+	}
+}
+
+// metricsHandler handles GET /metrics: total request/error counts and
+// process uptime since startup. Only registered when cfg.EnableMetrics is
+// true.
+func metricsHandler(m *Metrics) handlerFunc {
+	return func(ctx *Context) {
+		ctx.Success(gin.H{
+			"requests":    m.requestCount.Load(),
+			"errors":      m.errorCount.Load(),
+			"uptime_secs": int64(time.Since(m.startedAt).Seconds()),
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+// This file replaces the old raw offset/limit pagination with a unified
+// scheme shared by every list endpoint. Clients can either page by
+// number (?page=&per_page=), which is simple but can skip or repeat rows
+// if the underlying dataset changes between requests (e.g. a hot
+// reload), or follow an opaque ?cursor= token that pins both a last-seen
+// position and a hash of the filter that produced it, so paging through
+// a large filtered result set stays stable even across a reload.
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PageInfo is the pagination envelope embedded in every list response.
+// next_page/prev_page are omitted (nil -> JSON null) at the ends of the
+// result set.
+type PageInfo struct {
+	Page       int    `json:"page,omitempty"`
+	PerPage    int    `json:"per_page"`
+	Total      int    `json:"total"`
+	TotalPages int    `json:"total_pages,omitempty"`
+	FirstPage  int    `json:"first_page,omitempty"`
+	LastPage   int    `json:"last_page,omitempty"`
+	NextPage   *int   `json:"next_page,omitempty"`
+	PrevPage   *int   `json:"prev_page,omitempty"`
+	Cursor     string `json:"cursor,omitempty"`      // the cursor that produced this page, if cursor mode was used
+	NextCursor string `json:"next_cursor,omitempty"` // pass this as ?cursor= to fetch the next page
+}
+
+// clampPerPage normalizes a requested per_page value against cfg's
+// default and configured maximum.
+func clampPerPage(cfg *Config, requested int) int {
+	if requested <= 0 {
+		return cfg.DefaultLimit
+	}
+	if requested > cfg.MaxItemsPerPage {
+		return cfg.MaxItemsPerPage
+	}
+	return requested
+}
+
+// pagePagination slices data using 1-based page numbers and returns the
+// page's PageInfo.
+func pagePagination(cfg *Config, data []Item, page, perPage int) ([]Item, PageInfo) {
+	perPage = clampPerPage(cfg, perPage)
+	if page < 1 {
+		page = 1
+	}
+
+	total := len(data)
+	totalPages := (total + perPage - 1) / perPage
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	start := (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+
+	info := PageInfo{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+		FirstPage:  1,
+		LastPage:   totalPages,
+	}
+	if page > 1 {
+		prev := page - 1
+		info.PrevPage = &prev
+	}
+	if page < totalPages {
+		next := page + 1
+		info.NextPage = &next
+	}
+	return data[start:end], info
+}
+
+// filterHash derives a short, stable hash of whatever filter produced a
+// result set (e.g. "type=WINE"), so a cursor minted against one filter
+// can't be replayed against a different one after the dataset reloads.
+func filterHash(filterKey string) string {
+	sum := sha256.Sum256([]byte(filterKey))
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
+
+// encodeCursor packs a last-seen index and the filter hash it was
+// produced under into an opaque, base64-encoded token.
+func encodeCursor(lastIndex int, filterKey string) string {
+	raw := fmt.Sprintf("%d:%s", lastIndex, filterHash(filterKey))
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor unpacks a cursor token minted by encodeCursor and verifies
+// it was produced under the same filter.
+func decodeCursor(cursor, filterKey string) (lastIndex int, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("malformed cursor")
+	}
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("malformed cursor")
+	}
+	lastIndex, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("malformed cursor")
+	}
+	if parts[1] != filterHash(filterKey) {
+		return 0, fmt.Errorf("cursor does not match the current filter")
+	}
+	return lastIndex, nil
+}
+
+// cursorPagination slices data starting just after the position encoded
+// in cursor (or from the start, if cursor is empty), returning up to
+// perPage items and the PageInfo to continue from.
+func cursorPagination(cfg *Config, data []Item, cursor, filterKey string, perPage int) ([]Item, PageInfo, error) {
+	perPage = clampPerPage(cfg, perPage)
+
+	start := 0
+	if cursor != "" {
+		lastIndex, err := decodeCursor(cursor, filterKey)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+		start = lastIndex + 1
+	}
+	if start > len(data) {
+		start = len(data)
+	}
+	end := start + perPage
+	if end > len(data) {
+		end = len(data)
+	}
+
+	page := data[start:end]
+	info := PageInfo{
+		PerPage: perPage,
+		Total:   len(data),
+		Cursor:  cursor,
+	}
+	if end < len(data) {
+		info.NextCursor = encodeCursor(end-1, filterKey)
+	}
+	return page, info, nil
+}
+
+// RenderList is the shared response envelope for every list endpoint
+// (getAllItems, getItemsByType, getItemsBySupplier, ...), so they stop
+// each hand-rolling the same gin.H construction. filterKey identifies the
+// filter that produced data (e.g. "type=WINE", or "" for the unfiltered
+// list) and is used to scope cursors to that filter. extra carries any
+// handler-specific fields (like the "Found N items..." message) to merge
+// into the envelope.
+func RenderList(ctx *Context, cfg *Config, data []Item, filterKey string, extra gin.H) {
+	perPage := clampPerPage(cfg, mustAtoi(ctx.DefaultQuery("per_page", "")))
+
+	if cursor, ok := ctx.GetQuery("cursor"); ok {
+		paged, info, err := cursorPagination(cfg, data, cursor, filterKey, perPage)
+		if err != nil {
+			ctx.DataFormat(err)
+			return
+		}
+		body := gin.H{"count": len(paged), "pagination": info, "data": paged}
+		for k, v := range extra {
+			body[k] = v
+		}
+		ctx.Success(body)
+		return
+	}
+
+	page := mustAtoi(ctx.DefaultQuery("page", "1"))
+	paged, info := pagePagination(cfg, data, page, perPage)
+	body := gin.H{"count": len(paged), "pagination": info, "data": paged}
+	for k, v := range extra {
+		body[k] = v
+	}
+	ctx.Success(body)
+}
+
+// mustAtoi parses s as an int, defaulting to 0 on empty input or a parse
+// failure - used for query parameters where an invalid value should just
+// fall back to the pagination defaults rather than erroring the request.
+func mustAtoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,104 @@
+package main
+
+import "testing"
+
+func testConfig() *Config {
+	return &Config{MaxItemsPerPage: 50, DefaultLimit: 10}
+}
+
+func TestClampPerPage(t *testing.T) {
+	cfg := testConfig()
+	cases := []struct {
+		requested int
+		want      int
+	}{
+		{requested: 0, want: cfg.DefaultLimit},
+		{requested: -5, want: cfg.DefaultLimit},
+		{requested: 20, want: 20},
+		{requested: 1000, want: cfg.MaxItemsPerPage},
+	}
+	for _, c := range cases {
+		if got := clampPerPage(cfg, c.requested); got != c.want {
+			t.Errorf("clampPerPage(%d) = %d, want %d", c.requested, got, c.want)
+		}
+	}
+}
+
+func TestPagePagination(t *testing.T) {
+	cfg := testConfig()
+	data := make([]Item, 25)
+	for i := range data {
+		data[i] = Item{ItemCode: string(rune('a' + i))}
+	}
+
+	page, info := pagePagination(cfg, data, 2, 10)
+	if len(page) != 10 {
+		t.Fatalf("expected 10 items on page 2, got %d", len(page))
+	}
+	if page[0].ItemCode != data[10].ItemCode {
+		t.Fatalf("page 2 should start at index 10, got item %q", page[0].ItemCode)
+	}
+	if info.TotalPages != 3 {
+		t.Fatalf("expected 3 total pages for 25 items at 10/page, got %d", info.TotalPages)
+	}
+	if info.PrevPage == nil || *info.PrevPage != 1 {
+		t.Fatalf("expected prev_page 1, got %v", info.PrevPage)
+	}
+	if info.NextPage == nil || *info.NextPage != 3 {
+		t.Fatalf("expected next_page 3, got %v", info.NextPage)
+	}
+
+	lastPage, lastInfo := pagePagination(cfg, data, 3, 10)
+	if len(lastPage) != 5 {
+		t.Fatalf("expected 5 items on the last page, got %d", len(lastPage))
+	}
+	if lastInfo.NextPage != nil {
+		t.Fatalf("expected no next_page on the last page, got %v", lastInfo.NextPage)
+	}
+}
+
+func TestCursorPaginationRoundTrip(t *testing.T) {
+	cfg := testConfig()
+	data := make([]Item, 15)
+	for i := range data {
+		data[i] = Item{ItemCode: string(rune('a' + i))}
+	}
+
+	first, info, err := cursorPagination(cfg, data, "", "type=WINE", 10)
+	if err != nil {
+		t.Fatalf("cursorPagination: unexpected error: %v", err)
+	}
+	if len(first) != 10 {
+		t.Fatalf("expected 10 items in the first page, got %d", len(first))
+	}
+	if info.NextCursor == "" {
+		t.Fatalf("expected a next_cursor since more items remain")
+	}
+
+	second, info2, err := cursorPagination(cfg, data, info.NextCursor, "type=WINE", 10)
+	if err != nil {
+		t.Fatalf("cursorPagination with cursor: unexpected error: %v", err)
+	}
+	if len(second) != 5 {
+		t.Fatalf("expected the remaining 5 items, got %d", len(second))
+	}
+	if info2.NextCursor != "" {
+		t.Fatalf("expected no next_cursor once the data is exhausted")
+	}
+	if second[0].ItemCode != data[10].ItemCode {
+		t.Fatalf("second page should resume right after the first, got item %q", second[0].ItemCode)
+	}
+}
+
+func TestDecodeCursorRejectsFilterMismatch(t *testing.T) {
+	cursor := encodeCursor(4, "type=WINE")
+	if _, err := decodeCursor(cursor, "type=BEER"); err == nil {
+		t.Fatalf("expected decodeCursor to reject a cursor minted under a different filter")
+	}
+	if _, err := decodeCursor("not-base64!!", "type=WINE"); err == nil {
+		t.Fatalf("expected decodeCursor to reject a malformed cursor")
+	}
+	if _, err := decodeCursor(cursor, "type=WINE"); err != nil {
+		t.Fatalf("decodeCursor: unexpected error for a matching filter: %v", err)
+	}
+}
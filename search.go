@@ -0,0 +1,513 @@
+package main
+
+// This file adds full-text search with structured filters on top of the
+// in-memory Item dataset. Search is routed through a SearchBackend
+// interface so the storage/indexing strategy can be swapped without
+// touching the HTTP handler: today that's a simple in-memory scanner,
+// but a Meilisearch-backed index can be dropped in and "blue/green"
+// swapped in for zero-downtime reindexing.
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meilisearch/meilisearch-go"
+)
+
+// SearchResult is a single match returned from a SearchBackend, along with
+// the item it points to. Backends that can't produce a meaningful score
+// (like the in-memory scanner) just leave it at zero.
+type SearchResult struct {
+	Item  Item    `json:"item"`
+	Score float64 `json:"score"`
+}
+
+// SearchFacets holds the faceted counts we surface alongside search
+// results, e.g. "how many WINE items matched" or "how many came from
+// supplier X". Keyed by the facet value, valued by the match count.
+type SearchFacets struct {
+	ItemType map[string]int `json:"item_type"`
+	Supplier map[string]int `json:"supplier"`
+}
+
+// SearchBackend is implemented by anything that can index a set of Items
+// and answer typo-tolerant text queries plus structured filters against
+// them. Implementations are expected to be safe to call concurrently with
+// Reindex, since a hot CSV reload may trigger a reindex mid-request.
+type SearchBackend interface {
+	// Reindex replaces the backend's view of the dataset with items.
+	Reindex(items []Item) error
+	// Search runs a typo-tolerant text query (query may be empty) combined
+	// with a parsed filter expression (filter may be nil for "no filter"),
+	// and returns up to limit results along with facet counts computed
+	// over the full (unpaginated) match set.
+	Search(query string, filter *filterExpr, limit int) ([]SearchResult, SearchFacets, error)
+}
+
+// --- filter DSL -------------------------------------------------------
+//
+// We support a small, deliberately simple filter language of the form:
+//
+//	<field> <op> <value> [AND <field> <op> <value> ...]
+//
+// e.g. "year=2020 AND retail_sales>100". Only AND-chains are supported;
+// there's no OR/grouping. That's enough for the filters this API exposes
+// today, and it keeps parsing a single pass over the tokens.
+
+type filterOp string
+
+const (
+	opEq filterOp = "="
+	opGt filterOp = ">"
+	opLt filterOp = "<"
+)
+
+// filterCond is one "field op value" condition in a filterExpr.
+type filterCond struct {
+	field string
+	op    filterOp
+	// Exactly one of strVal/numVal is used, depending on the field.
+	strVal string
+	numVal float64
+}
+
+// filterExpr is an AND-chain of filterConds.
+type filterExpr struct {
+	conds []filterCond
+}
+
+// parseFilter parses the structured filter portion of a /search query,
+// e.g. "year=2020 AND retail_sales>100". An empty string is valid and
+// means "no filter".
+func parseFilter(raw string) (*filterExpr, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(raw, " AND ")
+	expr := &filterExpr{conds: make([]filterCond, 0, len(parts))}
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		cond, err := parseCondition(part)
+		if err != nil {
+			return nil, err
+		}
+		expr.conds = append(expr.conds, cond)
+	}
+	return expr, nil
+}
+
+// parseCondition parses a single "field op value" clause.
+func parseCondition(clause string) (filterCond, error) {
+	for _, op := range []filterOp{opEq, opGt, opLt} {
+		idx := strings.Index(clause, string(op))
+		if idx <= 0 {
+			continue
+		}
+		field := strings.TrimSpace(clause[:idx])
+		value := strings.TrimSpace(clause[idx+1:])
+		if !isFilterableField(field) {
+			return filterCond{}, fmt.Errorf("unknown filter field %q", field)
+		}
+
+		cond := filterCond{field: field, op: op}
+		if num, err := strconv.ParseFloat(value, 64); err == nil {
+			cond.numVal = num
+		} else {
+			cond.strVal = value
+		}
+		return cond, nil
+	}
+	return filterCond{}, fmt.Errorf("could not parse filter clause %q", clause)
+}
+
+// isFilterableField reports whether field is one of the Item fields we
+// allow filtering on.
+func isFilterableField(field string) bool {
+	switch field {
+	case "year", "month", "retail_sales", "retail_transfers", "warehouse_sales", "item_type", "supplier":
+		return true
+	default:
+		return false
+	}
+}
+
+// matches reports whether item satisfies every condition in the filter.
+// A nil filter matches everything.
+func (f *filterExpr) matches(item Item) bool {
+	if f == nil {
+		return true
+	}
+	for _, cond := range f.conds {
+		if !cond.matches(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c filterCond) matches(item Item) bool {
+	switch c.field {
+	case "year":
+		return compareNum(float64(item.Year), c.op, c.numVal)
+	case "month":
+		return compareNum(float64(item.Month), c.op, c.numVal)
+	case "retail_sales":
+		return compareNum(item.RetailSales, c.op, c.numVal)
+	case "retail_transfers":
+		return compareNum(item.RetailTransfers, c.op, c.numVal)
+	case "warehouse_sales":
+		return compareNum(item.WarehouseSales, c.op, c.numVal)
+	case "item_type":
+		return compareStr(item.ItemType, c.op, c.strVal)
+	case "supplier":
+		return compareStr(item.Supplier, c.op, c.strVal)
+	default:
+		return false
+	}
+}
+
+func compareNum(field float64, op filterOp, value float64) bool {
+	switch op {
+	case opEq:
+		return field == value
+	case opGt:
+		return field > value
+	case opLt:
+		return field < value
+	default:
+		return false
+	}
+}
+
+func compareStr(field string, op filterOp, value string) bool {
+	// Only equality makes sense for string fields; > and < are ignored.
+	if op != opEq {
+		return false
+	}
+	return strings.EqualFold(field, value)
+}
+
+// --- in-memory backend -------------------------------------------------
+
+// InMemorySearchBackend is the default SearchBackend: it keeps a copy of
+// the dataset in memory and does a linear scan per query. It's fine for
+// the dataset sizes this API targets and requires no external service.
+// items is held behind an atomic.Pointer, the same pattern ItemStore uses
+// for the dataset snapshot, since Reindex (driven by a hot CSV reload) can
+// run concurrently with in-flight Search calls.
+type InMemorySearchBackend struct {
+	items atomic.Pointer[[]Item]
+}
+
+// NewInMemorySearchBackend creates an InMemorySearchBackend with an empty
+// index; call Reindex before serving search traffic.
+func NewInMemorySearchBackend() *InMemorySearchBackend {
+	return &InMemorySearchBackend{}
+}
+
+func (b *InMemorySearchBackend) Reindex(items []Item) error {
+	b.items.Store(&items)
+	return nil
+}
+
+func (b *InMemorySearchBackend) Search(query string, filter *filterExpr, limit int) ([]SearchResult, SearchFacets, error) {
+	facets := SearchFacets{ItemType: map[string]int{}, Supplier: map[string]int{}}
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var items []Item
+	if p := b.items.Load(); p != nil {
+		items = *p
+	}
+
+	var results []SearchResult
+	for _, item := range items {
+		if !filter.matches(item) {
+			continue
+		}
+		if query != "" && !textMatches(item, query) {
+			continue
+		}
+
+		facets.ItemType[item.ItemType]++
+		facets.Supplier[item.Supplier]++
+
+		if limit <= 0 || len(results) < limit {
+			results = append(results, SearchResult{Item: item})
+		}
+	}
+	return results, facets, nil
+}
+
+// textMatches is a deliberately forgiving "typo-tolerant" match: it does
+// a substring match against the lower-cased text fields, plus a cheap
+// edit-distance-1 check per word so small typos still hit. This is not a
+// real search engine - that's exactly what the Meilisearch backend below
+// is for.
+func textMatches(item Item, query string) bool {
+	haystacks := []string{
+		strings.ToLower(item.ItemDescription),
+		strings.ToLower(item.Supplier),
+		strings.ToLower(item.ItemCode),
+	}
+	for _, h := range haystacks {
+		if strings.Contains(h, query) {
+			return true
+		}
+		for _, word := range strings.Fields(h) {
+			if withinEditDistance1(word, query) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withinEditDistance1 reports whether a and b differ by at most one
+// single-character insertion, deletion, or substitution. It's a cheap
+// approximation of typo tolerance, not a general Levenshtein distance.
+func withinEditDistance1(a, b string) bool {
+	if a == b {
+		return true
+	}
+	la, lb := len(a), len(b)
+	if abs(la-lb) > 1 {
+		return false
+	}
+	// Same length: allow exactly one substitution.
+	if la == lb {
+		diff := 0
+		for i := range a {
+			if a[i] != b[i] {
+				diff++
+				if diff > 1 {
+					return false
+				}
+			}
+		}
+		return diff <= 1
+	}
+	// Differ by one in length: allow exactly one insertion/deletion.
+	longer, shorter := a, b
+	if len(shorter) > len(longer) {
+		longer, shorter = shorter, longer
+	}
+	i, j, skipped := 0, 0, false
+	for i < len(longer) && j < len(shorter) {
+		if longer[i] == shorter[j] {
+			i++
+			j++
+			continue
+		}
+		if skipped {
+			return false
+		}
+		skipped = true
+		i++
+	}
+	return true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// --- Meilisearch backend -------------------------------------------------
+
+// MeilisearchBackend delegates indexing and search to a Meilisearch
+// instance. It indexes Item records on load and re-indexes on every CSV
+// reload by writing into a freshly-named index and then swapping the
+// index alias, mirroring the blue/green index-switching pattern from the
+// calibre-api integration so readers never see a half-built index.
+type MeilisearchBackend struct {
+	client    meilisearch.ServiceManager
+	indexName string // the stable, externally-visible index name
+}
+
+// NewMeilisearchBackend connects to the Meilisearch instance at host using
+// apiKey, and serves queries against indexName.
+func NewMeilisearchBackend(host, apiKey, indexName string) *MeilisearchBackend {
+	client := meilisearch.New(host, meilisearch.WithAPIKey(apiKey))
+	return &MeilisearchBackend{client: client, indexName: indexName}
+}
+
+// meiliDocument is what actually gets indexed in Meilisearch. ItemCode
+// alone isn't unique - the same code recurs on a separate row for every
+// year/month, which is why Item carries Year/Month at all - so indexing
+// Items directly would have Meilisearch's primary-key upsert silently
+// collapse every row for a code down to just the last one written. ID
+// disambiguates rows sharing an ItemCode.
+type meiliDocument struct {
+	ID string `json:"id"`
+	Item
+}
+
+// meiliDocuments wraps items with a synthesized unique id, sanitized to
+// the character set Meilisearch allows in a primary key value
+// (letters, digits, '-' and '_').
+func meiliDocuments(items []Item) []meiliDocument {
+	docs := make([]meiliDocument, len(items))
+	for i, item := range items {
+		id := fmt.Sprintf("%d_%d_%s", item.Year, item.Month, item.ItemCode)
+		docs[i] = meiliDocument{ID: sanitizeMeiliID(id), Item: item}
+	}
+	return docs
+}
+
+// sanitizeMeiliID replaces any character outside [A-Za-z0-9_-] with '_',
+// since item codes in the dataset may contain spaces or punctuation that
+// Meilisearch rejects in a primary key field.
+func sanitizeMeiliID(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// Reindex builds a brand-new index, bulk-loads items into it, then swaps
+// it in under the stable index name so in-flight queries keep hitting a
+// complete index instead of a partially-populated one.
+func (b *MeilisearchBackend) Reindex(items []Item) error {
+	stagingName := fmt.Sprintf("%s_build_%d", b.indexName, time.Now().Unix())
+
+	task, err := b.client.CreateIndex(&meilisearch.IndexConfig{
+		Uid:        stagingName,
+		PrimaryKey: "id",
+	})
+	if err != nil {
+		return fmt.Errorf("create staging index: %w", err)
+	}
+	if _, err := b.client.WaitForTask(task.TaskUID, 0); err != nil {
+		return fmt.Errorf("create staging index: %w", err)
+	}
+
+	index := b.client.Index(stagingName)
+	if _, err := index.AddDocuments(meiliDocuments(items), nil); err != nil {
+		return fmt.Errorf("index documents: %w", err)
+	}
+	filterable := []interface{}{
+		"item_type", "supplier", "year", "month",
+		"retail_sales", "retail_transfers", "warehouse_sales",
+	}
+	if _, err := index.UpdateFilterableAttributes(&filterable); err != nil {
+		return fmt.Errorf("set filterable attributes: %w", err)
+	}
+
+	// Blue/green swap: the staging index becomes the live one atomically
+	// from the caller's point of view.
+	swapTask, err := b.client.SwapIndexes([]*meilisearch.SwapIndexesParams{
+		{Indexes: []string{stagingName, b.indexName}},
+	})
+	if err != nil {
+		return fmt.Errorf("swap indexes: %w", err)
+	}
+	if _, err := b.client.WaitForTask(swapTask.TaskUID, 0); err != nil {
+		return fmt.Errorf("swap indexes: %w", err)
+	}
+
+	_, err = b.client.DeleteIndex(stagingName)
+	return err
+}
+
+func (b *MeilisearchBackend) Search(query string, filter *filterExpr, limit int) ([]SearchResult, SearchFacets, error) {
+	req := &meilisearch.SearchRequest{
+		Limit:  int64(limit),
+		Facets: []string{"item_type", "supplier"},
+	}
+	if filterStr := meiliFilterString(filter); filterStr != "" {
+		req.Filter = filterStr
+	}
+
+	res, err := b.client.Index(b.indexName).Search(query, req)
+	if err != nil {
+		return nil, SearchFacets{}, fmt.Errorf("meilisearch query: %w", err)
+	}
+
+	facets := SearchFacets{ItemType: map[string]int{}, Supplier: map[string]int{}}
+	if len(res.FacetDistribution) > 0 {
+		var dist map[string]map[string]int
+		if err := json.Unmarshal(res.FacetDistribution, &dist); err != nil {
+			return nil, SearchFacets{}, fmt.Errorf("decode facet distribution: %w", err)
+		}
+		facets.ItemType = dist["item_type"]
+		facets.Supplier = dist["supplier"]
+	}
+
+	results := make([]SearchResult, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		var item Item
+		if err := hit.Decode(&item); err != nil {
+			return nil, SearchFacets{}, fmt.Errorf("decode hit: %w", err)
+		}
+		results = append(results, SearchResult{Item: item})
+	}
+	return results, facets, nil
+}
+
+// meiliFilterString translates our filterExpr AND-chain into Meilisearch's
+// own filter syntax, which happens to be compatible for the operators we
+// support.
+func meiliFilterString(filter *filterExpr) string {
+	if filter == nil || len(filter.conds) == 0 {
+		return ""
+	}
+	clauses := make([]string, 0, len(filter.conds))
+	for _, c := range filter.conds {
+		var val string
+		if c.strVal == "" {
+			val = strconv.FormatFloat(c.numVal, 'f', -1, 64)
+		} else {
+			val = fmt.Sprintf("%q", c.strVal)
+		}
+		clauses = append(clauses, fmt.Sprintf("%s %s %s", c.field, c.op, val))
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+// --- HTTP handler -------------------------------------------------------
+
+// searchItemsHandler handles GET requests to /search?q=...&filter=...&limit=...
+// It runs a typo-tolerant text query plus an optional structured filter
+// against store's SearchBackend and returns matches with facet counts.
+func searchItemsHandler(store *ItemStore) handlerFunc {
+	return func(ctx *Context) {
+		query := ctx.Query("q")
+		limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+
+		filter, err := parseFilter(ctx.Query("filter"))
+		if err != nil {
+			ctx.DataFormat(err)
+			return
+		}
+
+		results, facets, err := store.search.Search(query, filter, limit)
+		if err != nil {
+			ctx.ServerError(err)
+			return
+		}
+
+		ctx.Success(gin.H{
+			"count":  len(results),
+			"query":  query,
+			"data":   results,
+			"facets": facets,
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import "testing"
+
+func TestParseFilter(t *testing.T) {
+	cases := []struct {
+		name    string
+		raw     string
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "empty is no filter", raw: "", wantNil: true},
+		{name: "single numeric condition", raw: "year=2020"},
+		{name: "AND chain", raw: "year=2020 AND retail_sales>100"},
+		{name: "unknown field rejected", raw: "bogus=1", wantErr: true},
+		{name: "unparsable clause rejected", raw: "year", wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			expr, err := parseFilter(c.raw)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("parseFilter(%q): expected an error, got nil", c.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFilter(%q): unexpected error: %v", c.raw, err)
+			}
+			if c.wantNil && expr != nil {
+				t.Fatalf("parseFilter(%q): expected a nil filterExpr, got %+v", c.raw, expr)
+			}
+		})
+	}
+}
+
+func TestFilterExprMatches(t *testing.T) {
+	item := Item{Year: 2020, RetailSales: 150, ItemType: "WINE"}
+
+	expr, err := parseFilter("year=2020 AND retail_sales>100 AND item_type=wine")
+	if err != nil {
+		t.Fatalf("parseFilter: unexpected error: %v", err)
+	}
+	if !expr.matches(item) {
+		t.Fatalf("expected item to match %+v", expr)
+	}
+
+	expr, err = parseFilter("retail_sales<100")
+	if err != nil {
+		t.Fatalf("parseFilter: unexpected error: %v", err)
+	}
+	if expr.matches(item) {
+		t.Fatalf("did not expect item to match %+v", expr)
+	}
+}
+
+func TestWithinEditDistance1(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"wine", "wine", true},   // identical
+		{"wine", "wane", true},   // one substitution
+		{"wine", "win", true},    // one deletion
+		{"wine", "wines", true},  // one insertion
+		{"wine", "beer", false},  // too different
+		{"wine", "wxyne", false}, // two edits
+	}
+	for _, c := range cases {
+		if got := withinEditDistance1(c.a, c.b); got != c.want {
+			t.Errorf("withinEditDistance1(%q, %q) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
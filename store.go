@@ -0,0 +1,177 @@
+package main
+
+// ItemStore owns the live dataset snapshot and the search index built
+// from it. Handlers no longer reach into a package-level `items` global;
+// they're handed a *ItemStore (via addRoutes) and read/write through its
+// methods, so the snapshot and the search index can never drift out of
+// sync with each other.
+
+import (
+	"log"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// datasetStatus is a point-in-time summary of the loaded dataset, served
+// by GET /admin/status.
+type datasetStatus struct {
+	Version        int       `json:"version"`          // increments on every successful (re)load
+	RowCount       int       `json:"row_count"`        // number of items in the current snapshot
+	LoadDurationMS int64     `json:"load_duration_ms"` // how long the (re)load took
+	LastReload     time.Time `json:"last_reload"`      // when the current snapshot was loaded
+}
+
+// ItemStore holds the current dataset behind an atomic.Pointer, so
+// readers always see a complete, consistent slice even while a reload is
+// swapping in a new one.
+type ItemStore struct {
+	csvPath string
+	search  SearchBackend
+
+	items  atomic.Pointer[[]Item]
+	status atomic.Pointer[datasetStatus]
+}
+
+// NewItemStore builds the search backend described by cfg and returns an
+// ItemStore ready to be loaded via Reload.
+func NewItemStore(cfg *Config) (*ItemStore, error) {
+	backend, err := newSearchBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &ItemStore{csvPath: cfg.CSVPath, search: backend}, nil
+}
+
+// newSearchBackend constructs the SearchBackend configured by
+// cfg.SearchBackend, defaulting to the in-memory scanner.
+func newSearchBackend(cfg *Config) (SearchBackend, error) {
+	switch cfg.SearchBackend {
+	case "", "memory":
+		return NewInMemorySearchBackend(), nil
+	case "meilisearch":
+		return NewMeilisearchBackend(cfg.MeilisearchHost, cfg.MeilisearchAPIKey, cfg.MeilisearchIndexName), nil
+	default:
+		return nil, errUnknownSearchBackend(cfg.SearchBackend)
+	}
+}
+
+type errUnknownSearchBackend string
+
+func (e errUnknownSearchBackend) Error() string {
+	return "unknown search_backend " + string(e)
+}
+
+// Current returns the dataset snapshot currently in effect.
+func (s *ItemStore) Current() []Item {
+	p := s.items.Load()
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// Swap atomically replaces the dataset snapshot.
+func (s *ItemStore) Swap(newItems []Item) {
+	s.items.Store(&newItems)
+}
+
+// Status returns the current dataset status, or a zero value if nothing
+// has loaded yet.
+func (s *ItemStore) Status() datasetStatus {
+	p := s.status.Load()
+	if p == nil {
+		return datasetStatus{}
+	}
+	return *p
+}
+
+// Reload re-parses the CSV at s.csvPath into a fresh slice and hands it to
+// Replace. It's shared by the startup load, the file watcher, and the
+// /admin/reload endpoint so all three behave identically.
+func (s *ItemStore) Reload() error {
+	start := time.Now()
+
+	newItems, err := readAllItems(s.csvPath)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Replace(newItems); err != nil {
+		return err
+	}
+	log.Printf("ItemStore.Reload: loaded %d rows in %s (version %d)", len(newItems), time.Since(start), s.Status().Version)
+	return nil
+}
+
+// Replace atomically swaps in newItems, reindexes search, and records the
+// result in s.status, so status always reflects whatever dataset is
+// actually live - whether it got there via Reload or via a direct mutation
+// like importItemsHandler merging an uploaded CSV into the current
+// dataset.
+func (s *ItemStore) Replace(newItems []Item) error {
+	start := time.Now()
+
+	s.Swap(newItems)
+	if err := s.search.Reindex(newItems); err != nil {
+		return err
+	}
+
+	version := s.Status().Version + 1
+	s.status.Store(&datasetStatus{
+		Version:        version,
+		RowCount:       len(newItems),
+		LoadDurationMS: time.Since(start).Milliseconds(),
+		LastReload:     time.Now(),
+	})
+	return nil
+}
+
+// Watch starts a background goroutine that watches s.csvPath for writes
+// and triggers Reload whenever the file changes, so the dataset stays
+// current without requiring a restart or a manual /admin/reload call.
+func (s *ItemStore) Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	// Watch the containing directory rather than the file itself: many
+	// editors and CSV-producing tools replace the file (rename over it)
+	// instead of writing in place, which a file-level watch would miss.
+	dir := filepath.Dir(s.csvPath)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Name != s.csvPath {
+					continue
+				}
+				if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+					continue
+				}
+				log.Printf("ItemStore.Watch: detected change to %s, reloading", s.csvPath)
+				if err := s.Reload(); err != nil {
+					log.Printf("ItemStore.Watch: reload failed: %v", err)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("ItemStore.Watch: watcher error: %v", err)
+			}
+		}
+	}()
+	return nil
+}